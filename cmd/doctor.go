@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	mirrorDir      = ".mirror"
+	mirrorDirPerm  = 0o700
+	mirrorFilePerm = 0o600
+)
+
+var errDoctorFailed = errors.New("doctor found problems")
+
+// doctorCheck is the outcome of a single environment check. A failed check
+// marked as warn is reported but does not make the command fail.
+type doctorCheck struct {
+	name   string
+	ok     bool
+	warn   bool
+	detail string
+	hint   string
+}
+
+func newDoctorCmd() *cobra.Command {
+	var fix bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the mctl environment",
+		Long: `Validate the environment mctl depends on: the git binary, SSH agent
+reachability and permissions on the .mirror control directory and its contents.
+With --fix, safe repairs (such as resetting .mirror permissions) are applied.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			steps := []func() doctorCheck{
+				func() doctorCheck { return checkGit(ctx) },
+				checkSSHAgent,
+				func() doctorCheck { return checkMirrorDir(mirrorDir, fix) },
+			}
+
+			checks := make([]doctorCheck, 0, len(steps))
+
+			for _, step := range steps {
+				checks = append(checks, step())
+
+				// Report what ran so far and skip the remaining checks.
+				if err := ctx.Err(); err != nil {
+					_ = reportChecks(cmd.OutOrStdout(), checks)
+
+					return fmt.Errorf("doctor interrupted: %w", err)
+				}
+			}
+
+			return reportChecks(cmd.OutOrStdout(), checks)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "apply safe repairs")
+
+	return cmd
+}
+
+func checkGit(ctx context.Context) doctorCheck {
+	check := doctorCheck{name: "git"}
+
+	path, err := exec.LookPath("git")
+	if err != nil {
+		check.detail = "git binary not found in PATH"
+		check.hint = "install git and make sure it is on PATH"
+
+		return check
+	}
+
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if ctx.Err() != nil {
+		check.detail = "interrupted"
+
+		return check
+	}
+
+	if err != nil {
+		check.detail = fmt.Sprintf("failed to run %s --version: %v", path, err)
+		check.hint = "check that the git installation is not corrupted"
+
+		return check
+	}
+
+	check.ok = true
+	check.detail = strings.TrimSpace(string(out))
+
+	return check
+}
+
+func checkSSHAgent() doctorCheck {
+	// Only repositories using ssh URLs need an agent.
+	check := doctorCheck{name: "ssh-agent", warn: true}
+
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		check.detail = "SSH_AUTH_SOCK is not set"
+		check.hint = "start ssh-agent and add your key if repositories use ssh URLs"
+
+		return check
+	}
+
+	conn, err := net.DialTimeout("unix", sock, 2*time.Second)
+	if err != nil {
+		check.detail = fmt.Sprintf("agent at %s is not reachable: %v", sock, err)
+		check.hint = "restart ssh-agent or update SSH_AUTH_SOCK"
+
+		return check
+	}
+
+	conn.Close()
+
+	check.ok = true
+	check.detail = "agent reachable at " + sock
+
+	return check
+}
+
+// checkMirrorDir verifies the modes under dir against the file permissions
+// in docs/core_specification.md §7.1: 0700 for directories, 0600 for files.
+// With fix, entries with a different mode are changed to the expected one.
+func checkMirrorDir(dir string, fix bool) doctorCheck {
+	check := doctorCheck{name: "permissions"}
+
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		check.ok = true
+		check.detail = dir + " not present in the current directory, skipped"
+
+		return check
+	}
+
+	if err != nil {
+		check.detail = fmt.Sprintf("cannot stat %s: %v", dir, err)
+
+		return check
+	}
+
+	if !info.IsDir() {
+		check.detail = dir + " is not a directory"
+
+		return check
+	}
+
+	if runtime.GOOS == "windows" {
+		check.ok = true
+		check.detail = "permission bits are not enforced on windows, skipped"
+
+		return check
+	}
+
+	// WalkDir does not follow a symlinked root, so walk its target instead.
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		check.detail = fmt.Sprintf("cannot resolve %s: %v", dir, err)
+
+		return check
+	}
+
+	var wrong, fixed []string
+
+	reported := map[string]bool{}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A directory that cannot be read has already been reported
+			// for its mode; anything else is a real error.
+			if reported[path] || reported[filepath.Dir(path)] {
+				reported[path] = true
+
+				return nil
+			}
+
+			return err
+		}
+
+		want := fs.FileMode(mirrorFilePerm)
+
+		switch {
+		case d.IsDir():
+			want = mirrorDirPerm
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			// Entries below a directory without the execute bit cannot be
+			// stat'ed; the directory itself is already reported.
+			if reported[filepath.Dir(path)] {
+				reported[path] = true
+
+				return nil
+			}
+
+			return err
+		}
+
+		perm := info.Mode().Perm()
+		if perm == want {
+			return nil
+		}
+
+		if fix {
+			if err := os.Chmod(path, want); err != nil {
+				return err
+			}
+
+			fixed = append(fixed, fmt.Sprintf("%s %04o -> %04o", path, perm, want))
+
+			return nil
+		}
+
+		reported[path] = true
+		wrong = append(wrong, fmt.Sprintf("%s is %04o, expected %04o", path, perm, want))
+
+		return nil
+	})
+	if err != nil {
+		check.detail = fmt.Sprintf("cannot check %s: %v", dir, err)
+
+		return check
+	}
+
+	switch {
+	case len(wrong) > 0:
+		check.detail = strings.Join(wrong, "; ")
+		check.hint = "run mctl doctor --fix"
+	case len(fixed) > 0:
+		check.ok = true
+		check.detail = "changed " + strings.Join(fixed, "; ")
+	default:
+		check.ok = true
+		check.detail = dir + " and its contents have the expected modes"
+	}
+
+	return check
+}
+
+func reportChecks(w io.Writer, checks []doctorCheck) error {
+	failed := 0
+
+	for _, c := range checks {
+		status := "ok"
+
+		switch {
+		case c.ok:
+		case c.warn:
+			status = "warn"
+		default:
+			status = "FAIL"
+			failed++
+		}
+
+		fmt.Fprintf(w, "[%s] %s: %s\n", status, c.name, c.detail)
+
+		if !c.ok && c.hint != "" {
+			fmt.Fprintf(w, "       fix: %s\n", c.hint)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d checks failed", errDoctorFailed, failed, len(checks))
+	}
+
+	return nil
+}
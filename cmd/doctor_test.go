@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMirrorDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on windows")
+	}
+
+	tests := []struct {
+		name     string
+		mode     fs.FileMode
+		file     bool
+		missing  bool
+		fix      bool
+		wantOK   bool
+		wantMode fs.FileMode
+	}{
+		{name: "0700", mode: 0o700, wantOK: true, wantMode: 0o700},
+		{name: "0755", mode: 0o755, wantMode: 0o755},
+		{name: "0000", mode: 0o000, wantMode: 0o000},
+		{name: "0500", mode: 0o500, wantMode: 0o500},
+		{name: "0644", mode: 0o644, wantMode: 0o644},
+		{name: "0600", mode: 0o600, wantMode: 0o600},
+		{name: "0755 fixed", mode: 0o755, fix: true, wantOK: true, wantMode: 0o700},
+		{name: "0000 fixed", mode: 0o000, fix: true, wantOK: true, wantMode: 0o700},
+		{name: "0500 fixed", mode: 0o500, fix: true, wantOK: true, wantMode: 0o700},
+		{name: "0644 fixed", mode: 0o644, fix: true, wantOK: true, wantMode: 0o700},
+		{name: "not a directory", mode: 0o644, file: true, wantMode: 0o644},
+		{name: "not a directory with fix", mode: 0o644, file: true, fix: true, wantMode: 0o644},
+		{name: "missing", missing: true, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), mirrorDir)
+
+			switch {
+			case tt.missing:
+			case tt.file:
+				require.NoError(t, os.WriteFile(dir, nil, tt.mode))
+			default:
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "metadata"), 0o700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "mirror.toml"), nil, 0o600))
+				require.NoError(t, os.Chmod(dir, tt.mode))
+				t.Cleanup(func() { _ = os.Chmod(dir, 0o700) })
+			}
+
+			check := checkMirrorDir(dir, tt.fix)
+			assert.Equal(t, tt.wantOK, check.ok, check.detail)
+
+			if !tt.wantOK && !tt.file {
+				assert.Contains(t, check.detail, fmt.Sprintf("%s is %04o", mirrorDir, tt.mode))
+				assert.NotEmpty(t, check.hint)
+			}
+
+			if tt.missing {
+				return
+			}
+
+			info, err := os.Stat(dir)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMode, info.Mode().Perm())
+		})
+	}
+}
+
+func TestCheckMirrorDirContents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), mirrorDir)
+	metadata := filepath.Join(dir, "metadata")
+	config := filepath.Join(dir, "mirror.toml")
+	meta := filepath.Join(metadata, "a1b2c3d4e5.json")
+
+	require.NoError(t, os.MkdirAll(metadata, 0o700))
+	require.NoError(t, os.Chmod(metadata, 0o755))
+	require.NoError(t, os.WriteFile(config, nil, 0o644))
+	require.NoError(t, os.WriteFile(meta, nil, 0o600))
+
+	check := checkMirrorDir(dir, false)
+	assert.False(t, check.ok)
+	assert.Contains(t, check.detail, config)
+	assert.Contains(t, check.detail, metadata)
+	assert.NotContains(t, check.detail, meta)
+
+	check = checkMirrorDir(dir, true)
+	assert.True(t, check.ok, check.detail)
+
+	for path, want := range map[string]fs.FileMode{
+		dir:      0o700,
+		metadata: 0o700,
+		config:   0o600,
+		meta:     0o600,
+	} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, info.Mode().Perm(), path)
+	}
+
+	assert.True(t, checkMirrorDir(dir, false).ok)
+}
+
+func TestCheckMirrorDirSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on windows")
+	}
+
+	base := t.TempDir()
+	target := filepath.Join(base, "control")
+	config := filepath.Join(target, "mirror.toml")
+	dir := filepath.Join(base, mirrorDir)
+
+	require.NoError(t, os.Mkdir(target, 0o755))
+	require.NoError(t, os.WriteFile(config, nil, 0o644))
+	require.NoError(t, os.Symlink(target, dir))
+
+	check := checkMirrorDir(dir, false)
+	assert.False(t, check.ok)
+	assert.Contains(t, check.detail, "control is 0755")
+	assert.Contains(t, check.detail, "mirror.toml is 0644")
+
+	assert.True(t, checkMirrorDir(dir, true).ok)
+
+	info, err := os.Stat(config)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestCheckGit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake git is a shell script")
+	}
+
+	tests := []struct {
+		name       string
+		script     string
+		wantOK     bool
+		wantDetail string
+	}{
+		{name: "found", script: "#!/bin/sh\necho 'git version 9.9.9'\n", wantOK: true, wantDetail: "git version 9.9.9"},
+		{name: "broken", script: "#!/bin/sh\nexit 1\n", wantDetail: "failed to run"},
+		{name: "missing", wantDetail: "not found in PATH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin := t.TempDir()
+			if tt.script != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(bin, "git"), []byte(tt.script), 0o755))
+			}
+
+			t.Setenv("PATH", bin)
+
+			check := checkGit(context.Background())
+			assert.Equal(t, tt.wantOK, check.ok, check.detail)
+			assert.Contains(t, check.detail, tt.wantDetail)
+
+			if !tt.wantOK {
+				assert.NotEmpty(t, check.hint)
+			}
+		})
+	}
+}
+
+func TestCheckGitInterrupted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake git is a shell script")
+	}
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "git"), []byte("#!/bin/sh\nexec sleep 10\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	check := checkGit(ctx)
+	assert.False(t, check.ok)
+	assert.Equal(t, "interrupted", check.detail)
+	assert.Empty(t, check.hint)
+}
+
+func TestCheckSSHAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("ssh-agent is not reached through a unix socket on windows")
+	}
+
+	// Unix socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "agent")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	sock := filepath.Join(dir, "agent.sock")
+
+	t.Run("unset", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+
+		check := checkSSHAgent()
+		assert.False(t, check.ok)
+		assert.True(t, check.warn)
+		assert.Contains(t, check.detail, "not set")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", sock)
+
+		check := checkSSHAgent()
+		assert.False(t, check.ok)
+		assert.True(t, check.warn)
+		assert.Contains(t, check.detail, "not reachable")
+	})
+
+	t.Run("reachable", func(t *testing.T) {
+		l, err := net.Listen("unix", sock)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = l.Close() })
+
+		t.Setenv("SSH_AUTH_SOCK", sock)
+
+		check := checkSSHAgent()
+		assert.True(t, check.ok, check.detail)
+		assert.Contains(t, check.detail, sock)
+	})
+}
+
+func TestReportChecks(t *testing.T) {
+	tests := []struct {
+		name      string
+		checks    []doctorCheck
+		wantLines []string
+		wantErr   string
+	}{
+		{
+			name:      "all ok",
+			checks:    []doctorCheck{{name: "git", ok: true, detail: "git version 2.0"}},
+			wantLines: []string{"[ok] git: git version 2.0"},
+		},
+		{
+			name: "warn only",
+			checks: []doctorCheck{
+				{name: "git", ok: true, detail: "git version 2.0"},
+				{name: "ssh-agent", warn: true, detail: "not set", hint: "start it"},
+			},
+			wantLines: []string{"[ok] git: git version 2.0", "[warn] ssh-agent: not set", "fix: start it"},
+		},
+		{
+			name: "failure",
+			checks: []doctorCheck{
+				{name: "git", ok: true, detail: "git version 2.0"},
+				{name: "ssh-agent", warn: true, detail: "not set"},
+				{name: "permissions", detail: "wrong", hint: "fix it"},
+			},
+			wantLines: []string{"[ok] git: git version 2.0", "[warn] ssh-agent: not set", "[FAIL] permissions: wrong", "fix: fix it"},
+			wantErr:   "1 of 3 checks failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := reportChecks(&out, tt.checks)
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, errDoctorFailed)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			for _, line := range tt.wantLines {
+				assert.Contains(t, out.String(), line)
+			}
+		})
+	}
+}
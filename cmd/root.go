@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 )
@@ -20,13 +24,24 @@ operations across multiple codebases while maintaining comprehensive metadata an
 
 	// Add all subcommands
 	cmd.AddCommand(newVersionCmd(version))
+	cmd.AddCommand(newDoctorCmd())
 
 	return cmd
 }
 
-// Execute invokes the command.
+// Execute invokes the command. SIGINT and SIGTERM cancel the command context,
+// which stops any git processes started with it; a second signal falls back
+// to the default handling and terminates the process.
 func Execute(version string) error {
-	if err := newRootCmd(version).Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := newRootCmd(version).ExecuteContext(ctx); err != nil {
 		return fmt.Errorf("error executing root command: %w", err)
 	}
 
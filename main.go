@@ -11,7 +11,7 @@ var version = ""
 
 func main() {
 	if err := cmd.Execute(version); err != nil {
-		fmt.Fprintf(os.Stderr, "%v", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 }